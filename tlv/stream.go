@@ -0,0 +1,177 @@
+//
+// stream.go
+//
+// Copyright (c) 2023 Markku Rossi
+//
+// All rights reserved.
+//
+
+package tlv
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// Encoder writes a stream of TLV-encoded values to an underlying
+// io.Writer, without buffering the whole stream in memory the way
+// Values.Marshal does.
+type Encoder struct {
+	w io.Writer
+}
+
+// NewEncoder creates a new Encoder that writes to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{
+		w: w,
+	}
+}
+
+// Encode writes v, tagged with the TLV type tag, to the stream. It
+// supports the same value types as Values.Marshal, plus any type
+// implementing Marshaler.
+func (e *Encoder) Encode(tag Type, v interface{}) error {
+	buf := new(bytes.Buffer)
+	if err := marshalValue(buf, tag, reflect.ValueOf(v)); err != nil {
+		return err
+	}
+	_, err := e.w.Write(buf.Bytes())
+	return err
+}
+
+// EncodeMap writes the values in v to the stream, in the same wire
+// format as Values.Marshal.
+func (e *Encoder) EncodeMap(v Values) error {
+	data, err := v.Marshal()
+	if err != nil {
+		return err
+	}
+	_, err = e.w.Write(data)
+	return err
+}
+
+// Decoder reads a stream of TLV-encoded values from an underlying
+// io.Reader, one tag at a time, without requiring the whole stream
+// to be buffered in memory the way Unmarshal does.
+type Decoder struct {
+	r *bufio.Reader
+
+	tag    Tag
+	length uint64
+	used   uint64
+}
+
+// NewDecoder creates a new Decoder that reads from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{
+		r: bufio.NewReader(r),
+	}
+}
+
+// Next reads the next tag and value length from the stream. The
+// value itself is left unread on the stream; call DecodeValue,
+// Reader, or Skip to consume it before calling Next again.
+func (d *Decoder) Next() (Tag, uint64, error) {
+	if d.used < d.length {
+		return 0, 0, fmt.Errorf("tlv: previous value not fully consumed")
+	}
+
+	ival, err := unmarshalIntReader(d.r)
+	if err != nil {
+		return 0, 0, err
+	}
+	length, err := unmarshalIntReader(d.r)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	d.tag = Tag(ival)
+	d.length = length
+	d.used = 0
+
+	return d.tag, length, nil
+}
+
+// DecodeValue reads the current value and decodes it into v, a
+// pointer to a Go value, using the same rules as UnmarshalStruct.
+func (d *Decoder) DecodeValue(v interface{}) error {
+	payload := make([]byte, d.length-d.used)
+	if _, err := io.ReadFull(d.r, payload); err != nil {
+		return err
+	}
+	d.used = d.length
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("tlv: DecodeValue: v must be a non-nil pointer")
+	}
+	return unmarshalValue(rv.Elem(), d.tag.VType(), payload)
+}
+
+// Skip discards the remaining bytes of the current value without
+// decoding them.
+func (d *Decoder) Skip() error {
+	n, err := io.CopyN(io.Discard, d.r, int64(d.length-d.used))
+	d.used += uint64(n)
+	return err
+}
+
+// Reader returns an io.Reader limited to the remaining bytes of the
+// current value, letting a VTData payload be streamed (e.g. to
+// disk) without being buffered in memory. The returned reader must
+// be read to EOF, or the value Skipped, before calling Next again.
+func (d *Decoder) Reader() io.Reader {
+	return &segmentReader{
+		d: d,
+		n: int64(d.length - d.used),
+	}
+}
+
+// segmentReader reads the remaining bytes of a Decoder's current
+// value, tracking how much of it has been consumed.
+type segmentReader struct {
+	d *Decoder
+	n int64
+}
+
+func (s *segmentReader) Read(p []byte) (int, error) {
+	if s.n <= 0 {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > s.n {
+		p = p[:s.n]
+	}
+	n, err := s.d.r.Read(p)
+	s.n -= int64(n)
+	s.d.used += uint64(n)
+	return n, err
+}
+
+// unmarshalIntReader reads a septet-encoded varint from r, the same
+// wire format as unmarshalInt, but from an io.ByteReader instead of
+// a byte slice, so the Decoder need not buffer the whole stream.
+func unmarshalIntReader(r io.ByteReader) (uint64, error) {
+	var result uint64
+	for i := 0; i < 5; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			if err == io.EOF {
+				return 0, ErrorEOF
+			}
+			return 0, err
+		}
+		bit := b & 0x80
+		val := b & 0x7f
+
+		result <<= 7
+		result |= uint64(val)
+
+		if bit == 0 {
+			return result, nil
+		}
+	}
+	return 0, ErrorEOF
+}
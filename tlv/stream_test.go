@@ -0,0 +1,190 @@
+//
+// stream_test.go
+//
+// Copyright (c) 2023 Markku Rossi
+//
+// All rights reserved.
+//
+
+package tlv
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	buf := new(bytes.Buffer)
+	enc := NewEncoder(buf)
+
+	if err := enc.Encode(Type(0), uint32(42)); err != nil {
+		t.Fatalf("Encode failed: %s\n", err)
+	}
+	if err := enc.Encode(Type(1), "hello"); err != nil {
+		t.Fatalf("Encode failed: %s\n", err)
+	}
+	if err := enc.EncodeMap(Values{
+		Type(2): uint8(7),
+	}); err != nil {
+		t.Fatalf("EncodeMap failed: %s\n", err)
+	}
+
+	dec := NewDecoder(buf)
+
+	tag, _, err := dec.Next()
+	if err != nil {
+		t.Fatalf("Next failed: %s\n", err)
+	}
+	if tag.Type() != Type(0) {
+		t.Fatalf("Type: got %d, expected 0", tag.Type())
+	}
+	var u32 uint32
+	if err := dec.DecodeValue(&u32); err != nil {
+		t.Fatalf("DecodeValue failed: %s\n", err)
+	}
+	if u32 != 42 {
+		t.Errorf("DecodeValue: got %d, expected 42", u32)
+	}
+
+	tag, _, err = dec.Next()
+	if err != nil {
+		t.Fatalf("Next failed: %s\n", err)
+	}
+	if tag.Type() != Type(1) {
+		t.Fatalf("Type: got %d, expected 1", tag.Type())
+	}
+	if err := dec.Skip(); err != nil {
+		t.Fatalf("Skip failed: %s\n", err)
+	}
+
+	tag, _, err = dec.Next()
+	if err != nil {
+		t.Fatalf("Next failed: %s\n", err)
+	}
+	if tag.Type() != Type(2) {
+		t.Fatalf("Type: got %d, expected 2", tag.Type())
+	}
+	var u8 uint8
+	if err := dec.DecodeValue(&u8); err != nil {
+		t.Fatalf("DecodeValue failed: %s\n", err)
+	}
+	if u8 != 7 {
+		t.Errorf("DecodeValue: got %d, expected 7", u8)
+	}
+
+	if _, _, err := dec.Next(); err != io.EOF && err != ErrorEOF {
+		t.Errorf("Next at EOF: got %v", err)
+	}
+}
+
+func TestEncodeDecodeNestedValues(t *testing.T) {
+	buf := new(bytes.Buffer)
+	enc := NewEncoder(buf)
+
+	nested := Values{Type(0): uint8(9)}
+	if err := enc.Encode(Type(0), nested); err != nil {
+		t.Fatalf("Encode failed: %s\n", err)
+	}
+
+	dec := NewDecoder(buf)
+	if _, _, err := dec.Next(); err != nil {
+		t.Fatalf("Next failed: %s\n", err)
+	}
+	var got Values
+	if err := dec.DecodeValue(&got); err != nil {
+		t.Fatalf("DecodeValue failed: %s\n", err)
+	}
+	if v, ok := got[Type(0)]; !ok || v.(uint8) != 9 {
+		t.Errorf("DecodeValue: got %v", got)
+	}
+}
+
+func TestEncodeDecodeSIntFloatArray(t *testing.T) {
+	buf := new(bytes.Buffer)
+	enc := NewEncoder(buf)
+
+	if err := enc.Encode(Type(0), int32(-12345)); err != nil {
+		t.Fatalf("Encode failed: %s\n", err)
+	}
+	if err := enc.Encode(Type(1), float64(3.5)); err != nil {
+		t.Fatalf("Encode failed: %s\n", err)
+	}
+	if err := enc.Encode(Type(2), []uint32{1, 2, 3}); err != nil {
+		t.Fatalf("Encode failed: %s\n", err)
+	}
+
+	dec := NewDecoder(buf)
+
+	if _, _, err := dec.Next(); err != nil {
+		t.Fatalf("Next failed: %s\n", err)
+	}
+	var i32 int32
+	if err := dec.DecodeValue(&i32); err != nil {
+		t.Fatalf("DecodeValue failed: %s\n", err)
+	}
+	if i32 != -12345 {
+		t.Errorf("DecodeValue: got %d, expected -12345", i32)
+	}
+
+	if _, _, err := dec.Next(); err != nil {
+		t.Fatalf("Next failed: %s\n", err)
+	}
+	var f64 float64
+	if err := dec.DecodeValue(&f64); err != nil {
+		t.Fatalf("DecodeValue failed: %s\n", err)
+	}
+	if f64 != 3.5 {
+		t.Errorf("DecodeValue: got %v, expected 3.5", f64)
+	}
+
+	if _, _, err := dec.Next(); err != nil {
+		t.Fatalf("Next failed: %s\n", err)
+	}
+	var arr []uint32
+	if err := dec.DecodeValue(&arr); err != nil {
+		t.Fatalf("DecodeValue failed: %s\n", err)
+	}
+	if len(arr) != 3 || arr[0] != 1 || arr[1] != 2 || arr[2] != 3 {
+		t.Errorf("DecodeValue: got %v, expected [1 2 3]", arr)
+	}
+}
+
+func TestEncodeNil(t *testing.T) {
+	buf := new(bytes.Buffer)
+	enc := NewEncoder(buf)
+
+	if err := enc.Encode(Type(0), nil); err == nil {
+		t.Errorf("Encode(nil) should fail, not panic")
+	}
+}
+
+func TestDecoderReader(t *testing.T) {
+	buf := new(bytes.Buffer)
+	enc := NewEncoder(buf)
+
+	payload := []byte("stream me to disk")
+	if err := enc.Encode(Type(0), payload); err != nil {
+		t.Fatalf("Encode failed: %s\n", err)
+	}
+
+	dec := NewDecoder(buf)
+	tag, length, err := dec.Next()
+	if err != nil {
+		t.Fatalf("Next failed: %s\n", err)
+	}
+	if tag.VType() != VTData {
+		t.Fatalf("VType: got %s, expected %s", tag.VType(), VTData)
+	}
+	if length != uint64(len(payload)) {
+		t.Fatalf("length: got %d, expected %d", length, len(payload))
+	}
+
+	got, err := io.ReadAll(dec.Reader())
+	if err != nil {
+		t.Fatalf("ReadAll failed: %s\n", err)
+	}
+	if !bytes.Equal(got, payload) {
+		t.Errorf("Reader(): got %q, expected %q", got, payload)
+	}
+}
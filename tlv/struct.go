@@ -0,0 +1,473 @@
+//
+// struct.go
+//
+// Copyright (c) 2023 Markku Rossi
+//
+// All rights reserved.
+//
+
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Marshaler is implemented by types that want to encode themselves
+// into a TLV value, instead of going through the struct-tag
+// reflection used by Marshal.
+type Marshaler interface {
+	MarshalTLV() (VType, []byte, error)
+}
+
+// Unmarshaler is implemented by types that want to decode
+// themselves from a TLV value, instead of going through the
+// struct-tag reflection used by UnmarshalStruct.
+type Unmarshaler interface {
+	UnmarshalTLV(vt VType, data []byte) error
+}
+
+var (
+	marshalerType   = reflect.TypeOf((*Marshaler)(nil)).Elem()
+	unmarshalerType = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
+	timeType        = reflect.TypeOf(time.Time{})
+	valuesType      = reflect.TypeOf(Values{})
+)
+
+// fieldInfo describes a struct field mapped by a `tlv:"..."` tag.
+type fieldInfo struct {
+	index     int
+	tlvType   Type
+	omitEmpty bool
+}
+
+// parseTag parses the `tlv:"<type>[,omitempty]"` tag of f. The
+// second return value is false if f has no tlv tag, or the tag is
+// "-", in which case the field is ignored by Marshal and
+// UnmarshalStruct.
+func parseTag(f reflect.StructField) (fieldInfo, bool) {
+	raw, ok := f.Tag.Lookup("tlv")
+	if !ok || raw == "-" {
+		return fieldInfo{}, false
+	}
+	parts := strings.Split(raw, ",")
+	n, err := strconv.ParseUint(parts[0], 10, 32)
+	if err != nil {
+		return fieldInfo{}, false
+	}
+	info := fieldInfo{
+		tlvType: Type(n),
+	}
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" {
+			info.omitEmpty = true
+		}
+	}
+	return info, true
+}
+
+// structFields returns the tagged, exported fields of the struct
+// type t, keyed by their TLV type.
+func structFields(t reflect.Type) map[Type]fieldInfo {
+	fields := make(map[Type]fieldInfo)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		info, ok := parseTag(f)
+		if !ok {
+			continue
+		}
+		info.index = i
+		fields[info.tlvType] = info
+	}
+	return fields
+}
+
+// Marshal encodes v, a struct or a pointer to a struct, into
+// TLV-encoded data. Struct fields are mapped to TLV types with
+// `tlv:"<type>[,omitempty]"` tags; fields without a tlv tag, or with
+// `tlv:"-"`, are skipped. A slice field (other than []byte, which
+// maps to VTData) is encoded by repeating its tag once per element;
+// UnmarshalStruct appends each matching tag back onto the
+// slice. Nil pointers are omitted. Types implementing Marshaler are
+// delegated to, for fields whose encoding Marshal does not know
+// about natively.
+func Marshal(v interface{}) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("tlv: Marshal: %s is not a struct", rv.Kind())
+	}
+
+	buf := new(bytes.Buffer)
+	if err := marshalStruct(buf, rv); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func marshalStruct(buf *bytes.Buffer, rv reflect.Value) error {
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		info, ok := parseTag(f)
+		if !ok {
+			continue
+		}
+		fv := rv.Field(i)
+
+		if info.omitEmpty && fv.IsZero() {
+			continue
+		}
+		if fv.Kind() == reflect.Ptr {
+			if fv.IsNil() {
+				continue
+			}
+			fv = fv.Elem()
+		}
+		if fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() != reflect.Uint8 {
+			for j := 0; j < fv.Len(); j++ {
+				if err := marshalValue(buf, info.tlvType, fv.Index(j)); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if err := marshalValue(buf, info.tlvType, fv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func marshalValue(buf *bytes.Buffer, typ Type, fv reflect.Value) error {
+	if !fv.IsValid() {
+		return fmt.Errorf("tlv: Marshal: nil value not supported")
+	}
+
+	var tag Tag
+	tag.SetType(typ)
+
+	if m, ok := asMarshaler(fv); ok {
+		vt, data, err := m.MarshalTLV()
+		if err != nil {
+			return err
+		}
+		tag.SetVType(vt)
+		marshalInt(uint64(tag), buf)
+		marshalInt(uint64(len(data)), buf)
+		buf.Write(data)
+		return nil
+	}
+
+	switch {
+	case fv.Type() == valuesType:
+		tag.SetVType(VTMap)
+		marshalInt(uint64(tag), buf)
+		d, err := fv.Interface().(Values).Marshal()
+		if err != nil {
+			return err
+		}
+		marshalInt(uint64(len(d)), buf)
+		buf.Write(d)
+
+	case fv.Type() == timeType:
+		tag.SetVType(VTInt)
+		marshalInt(uint64(tag), buf)
+		marshalInt(8, buf)
+		var tmp [8]byte
+		bo.PutUint64(tmp[:], uint64(fv.Interface().(time.Time).UnixNano()))
+		buf.Write(tmp[:])
+
+	case fv.Kind() == reflect.Struct:
+		tag.SetVType(VTMap)
+		marshalInt(uint64(tag), buf)
+		sub := new(bytes.Buffer)
+		if err := marshalStruct(sub, fv); err != nil {
+			return err
+		}
+		marshalInt(uint64(sub.Len()), buf)
+		buf.Write(sub.Bytes())
+
+	case fv.Kind() == reflect.Bool:
+		tag.SetVType(VTBool)
+		marshalInt(uint64(tag), buf)
+		buf.WriteByte(1)
+		if fv.Bool() {
+			buf.WriteByte(1)
+		} else {
+			buf.WriteByte(0)
+		}
+
+	case fv.Kind() == reflect.String:
+		tag.SetVType(VTString)
+		marshalInt(uint64(tag), buf)
+		data := []byte(fv.String())
+		marshalInt(uint64(len(data)), buf)
+		buf.Write(data)
+
+	case fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() == reflect.Uint8:
+		tag.SetVType(VTData)
+		marshalInt(uint64(tag), buf)
+		data := fv.Bytes()
+		marshalInt(uint64(len(data)), buf)
+		buf.Write(data)
+
+	case fv.Kind() >= reflect.Uint8 && fv.Kind() <= reflect.Uint64:
+		tag.SetVType(VTInt)
+		marshalInt(uint64(tag), buf)
+		width := intWidth(fv.Kind())
+		marshalInt(uint64(width), buf)
+		var tmp [8]byte
+		bo.PutUint64(tmp[:], fv.Uint())
+		buf.Write(tmp[8-width:])
+
+	case fv.Kind() >= reflect.Int && fv.Kind() <= reflect.Int64:
+		tag.SetVType(VTSInt)
+		marshalInt(uint64(tag), buf)
+		marshalSInt(fv.Int(), buf)
+
+	case fv.Kind() == reflect.Float32:
+		tag.SetVType(VTFloat)
+		marshalInt(uint64(tag), buf)
+		marshalInt(4, buf)
+		var tmp [4]byte
+		bo.PutUint32(tmp[:], math.Float32bits(float32(fv.Float())))
+		buf.Write(tmp[:])
+
+	case fv.Kind() == reflect.Float64:
+		tag.SetVType(VTFloat)
+		marshalInt(uint64(tag), buf)
+		marshalInt(8, buf)
+		var tmp [8]byte
+		bo.PutUint64(tmp[:], math.Float64bits(fv.Float()))
+		buf.Write(tmp[:])
+
+	case fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() != reflect.Uint8:
+		tag.SetVType(VTArray)
+		marshalInt(uint64(tag), buf)
+		d, err := marshalArray(fv)
+		if err != nil {
+			return err
+		}
+		marshalInt(uint64(len(d)), buf)
+		buf.Write(d)
+
+	default:
+		return fmt.Errorf("tlv: Marshal: field type %s not supported", fv.Type())
+	}
+	return nil
+}
+
+func asMarshaler(fv reflect.Value) (Marshaler, bool) {
+	if fv.Type().Implements(marshalerType) {
+		return fv.Interface().(Marshaler), true
+	}
+	if fv.CanAddr() && fv.Addr().Type().Implements(marshalerType) {
+		return fv.Addr().Interface().(Marshaler), true
+	}
+	return nil, false
+}
+
+func asUnmarshaler(fv reflect.Value) (Unmarshaler, bool) {
+	if fv.CanAddr() && fv.Addr().Type().Implements(unmarshalerType) {
+		return fv.Addr().Interface().(Unmarshaler), true
+	}
+	return nil, false
+}
+
+// intWidth returns the wire width, in bytes, used to encode unsigned
+// integer kind k: 1 for uint8, 2 for uint16, 4 for uint32, and 8 for
+// uint64 and the platform-sized uint.
+func intWidth(k reflect.Kind) int {
+	switch k {
+	case reflect.Uint8:
+		return 1
+	case reflect.Uint16:
+		return 2
+	case reflect.Uint32:
+		return 4
+	default:
+		return 8
+	}
+}
+
+// UnmarshalStruct decodes the TLV-encoded data into v, a pointer to
+// a struct, using the same `tlv:"<type>[,omitempty]"` tags as
+// Marshal. It is named UnmarshalStruct, rather than Unmarshal, to
+// avoid clashing with the existing Unmarshal, which decodes into a
+// Values map.
+func UnmarshalStruct(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("tlv: UnmarshalStruct: v must be a non-nil pointer")
+	}
+	rv = rv.Elem()
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("tlv: UnmarshalStruct: %s is not a struct", rv.Kind())
+	}
+	return unmarshalStruct(data, rv)
+}
+
+func unmarshalStruct(data []byte, rv reflect.Value) error {
+	fields := structFields(rv.Type())
+	ofs := 0
+
+	for ofs < len(data) {
+		ival, next, err := unmarshalInt(data, ofs)
+		if err != nil {
+			return err
+		}
+		tag := Tag(ival)
+		length, next2, err := unmarshalInt(data, next)
+		if err != nil {
+			return err
+		}
+		ofs = next2
+		if ofs+int(length) > len(data) {
+			return ErrorTruncated
+		}
+		payload := data[ofs : ofs+int(length)]
+		ofs += int(length)
+
+		info, ok := fields[tag.Type()]
+		if !ok {
+			continue
+		}
+		fv := rv.Field(info.index)
+
+		if fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() != reflect.Uint8 {
+			elem := reflect.New(fv.Type().Elem()).Elem()
+			if err := unmarshalValue(elem, tag.VType(), payload); err != nil {
+				return err
+			}
+			fv.Set(reflect.Append(fv, elem))
+			continue
+		}
+
+		if fv.Kind() == reflect.Ptr {
+			if fv.IsNil() {
+				fv.Set(reflect.New(fv.Type().Elem()))
+			}
+			fv = fv.Elem()
+		}
+
+		if err := unmarshalValue(fv, tag.VType(), payload); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func unmarshalValue(fv reflect.Value, vt VType, payload []byte) error {
+	if u, ok := asUnmarshaler(fv); ok {
+		return u.UnmarshalTLV(vt, payload)
+	}
+
+	switch {
+	case fv.Type() == valuesType:
+		if vt != VTMap {
+			return fmt.Errorf("tlv: expected map, got %s", vt)
+		}
+		m, err := Unmarshal(payload)
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(m))
+
+	case fv.Type() == timeType:
+		if vt != VTInt || len(payload) != 8 {
+			return fmt.Errorf("tlv: invalid time.Time encoding")
+		}
+		fv.Set(reflect.ValueOf(time.Unix(0, int64(bo.Uint64(payload)))))
+
+	case fv.Kind() == reflect.Struct:
+		if vt != VTMap {
+			return fmt.Errorf("tlv: expected map, got %s", vt)
+		}
+		return unmarshalStruct(payload, fv)
+
+	case fv.Kind() == reflect.Bool:
+		if len(payload) != 1 {
+			return fmt.Errorf("tlv: invalid bool data length %d", len(payload))
+		}
+		fv.SetBool(payload[0] != 0)
+
+	case fv.Kind() == reflect.String:
+		fv.SetString(string(payload))
+
+	case fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() == reflect.Uint8:
+		data := make([]byte, len(payload))
+		copy(data, payload)
+		fv.SetBytes(data)
+
+	case fv.Kind() >= reflect.Uint8 && fv.Kind() <= reflect.Uint64:
+		var val uint64
+		switch len(payload) {
+		case 1:
+			val = uint64(payload[0])
+		case 2:
+			val = uint64(bo.Uint16(payload))
+		case 4:
+			val = uint64(bo.Uint32(payload))
+		case 8:
+			val = bo.Uint64(payload)
+		default:
+			return fmt.Errorf("tlv: invalid integer data length %d", len(payload))
+		}
+		fv.SetUint(val)
+
+	case fv.Kind() >= reflect.Int && fv.Kind() <= reflect.Int64:
+		sval, _, err := unmarshalVarint(payload, 0)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(zigzagDecode(sval))
+
+	case fv.Kind() == reflect.Float32:
+		if len(payload) != 4 {
+			return fmt.Errorf("tlv: invalid float32 data length %d", len(payload))
+		}
+		fv.SetFloat(float64(math.Float32frombits(bo.Uint32(payload))))
+
+	case fv.Kind() == reflect.Float64:
+		if len(payload) != 8 {
+			return fmt.Errorf("tlv: invalid float64 data length %d", len(payload))
+		}
+		fv.SetFloat(math.Float64frombits(bo.Uint64(payload)))
+
+	case fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() != reflect.Uint8:
+		if vt != VTArray {
+			return fmt.Errorf("tlv: expected array, got %s", vt)
+		}
+		arr, err := unmarshalArray(payload)
+		if err != nil {
+			return err
+		}
+		av := reflect.ValueOf(arr)
+		if av.Type() != fv.Type() {
+			return fmt.Errorf("tlv: array element type mismatch: got %s, expected %s", av.Type(), fv.Type())
+		}
+		fv.Set(av)
+
+	default:
+		return fmt.Errorf("tlv: UnmarshalStruct: field type %s not supported", fv.Type())
+	}
+	return nil
+}
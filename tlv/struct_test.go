@@ -0,0 +1,156 @@
+//
+// struct_test.go
+//
+// Copyright (c) 2023 Markku Rossi
+//
+// All rights reserved.
+//
+
+package tlv
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+type innerS struct {
+	Count uint32 `tlv:"0"`
+}
+
+type structS struct {
+	Name     string    `tlv:"0"`
+	Age      uint8     `tlv:"1"`
+	Data     []byte    `tlv:"2"`
+	Created  time.Time `tlv:"3"`
+	Inner    innerS    `tlv:"4"`
+	Tags     []string  `tlv:"5"`
+	Optional *uint32   `tlv:"6,omitempty"`
+	Ignored  string    `tlv:"-"`
+	Hidden   string
+}
+
+func TestStructMarshal(t *testing.T) {
+	now := time.Unix(1700000000, 123000000)
+	in := structS{
+		Name:    "foo",
+		Age:     42,
+		Data:    []byte{1, 2, 3},
+		Created: now,
+		Inner:   innerS{Count: 7},
+		Tags:    []string{"a", "b", "c"},
+		Ignored: "not encoded",
+		Hidden:  "not encoded",
+	}
+
+	data, err := Marshal(&in)
+	if err != nil {
+		t.Fatalf("Marshal failed: %s\n", err)
+	}
+
+	var out structS
+	if err := UnmarshalStruct(data, &out); err != nil {
+		t.Fatalf("UnmarshalStruct failed: %s\n", err)
+	}
+
+	if out.Name != in.Name {
+		t.Errorf("Name: got %q, expected %q", out.Name, in.Name)
+	}
+	if out.Age != in.Age {
+		t.Errorf("Age: got %d, expected %d", out.Age, in.Age)
+	}
+	if !bytes.Equal(out.Data, in.Data) {
+		t.Errorf("Data: got %x, expected %x", out.Data, in.Data)
+	}
+	if !out.Created.Equal(in.Created) {
+		t.Errorf("Created: got %s, expected %s", out.Created, in.Created)
+	}
+	if out.Inner.Count != in.Inner.Count {
+		t.Errorf("Inner.Count: got %d, expected %d", out.Inner.Count, in.Inner.Count)
+	}
+	if len(out.Tags) != len(in.Tags) {
+		t.Fatalf("Tags: got %v, expected %v", out.Tags, in.Tags)
+	}
+	for i := range in.Tags {
+		if out.Tags[i] != in.Tags[i] {
+			t.Errorf("Tags[%d]: got %q, expected %q", i, out.Tags[i], in.Tags[i])
+		}
+	}
+	if out.Optional != nil {
+		t.Errorf("Optional: got %v, expected nil", out.Optional)
+	}
+	if out.Ignored != "" {
+		t.Errorf("Ignored: got %q, expected empty", out.Ignored)
+	}
+}
+
+type numericS struct {
+	Count  int32    `tlv:"0"`
+	Amount float64  `tlv:"1"`
+	Scores []uint32 `tlv:"2"`
+}
+
+func TestStructMarshalSIntFloatArray(t *testing.T) {
+	in := numericS{
+		Count:  -12345,
+		Amount: 1.5,
+		Scores: []uint32{1, 2, 3},
+	}
+
+	data, err := Marshal(&in)
+	if err != nil {
+		t.Fatalf("Marshal failed: %s\n", err)
+	}
+
+	var out numericS
+	if err := UnmarshalStruct(data, &out); err != nil {
+		t.Fatalf("UnmarshalStruct failed: %s\n", err)
+	}
+
+	if out.Count != in.Count {
+		t.Errorf("Count: got %d, expected %d", out.Count, in.Count)
+	}
+	if out.Amount != in.Amount {
+		t.Errorf("Amount: got %v, expected %v", out.Amount, in.Amount)
+	}
+	if len(out.Scores) != len(in.Scores) {
+		t.Fatalf("Scores: got %v, expected %v", out.Scores, in.Scores)
+	}
+	for i := range in.Scores {
+		if out.Scores[i] != in.Scores[i] {
+			t.Errorf("Scores[%d]: got %d, expected %d", i, out.Scores[i], in.Scores[i])
+		}
+	}
+}
+
+type boolS struct {
+	Flag bool `tlv:"0"`
+}
+
+func TestUnmarshalStructZeroLengthBool(t *testing.T) {
+	// tag=0/VTBool, length=0: a malformed encoding of a bool value.
+	data := []byte{0x00, 0x00}
+
+	var out boolS
+	if err := UnmarshalStruct(data, &out); err == nil {
+		t.Errorf("UnmarshalStruct() should fail on a zero-length bool, not panic")
+	}
+}
+
+func TestStructOmitEmptyPointer(t *testing.T) {
+	val := uint32(9)
+	in := structS{Optional: &val}
+
+	data, err := Marshal(&in)
+	if err != nil {
+		t.Fatalf("Marshal failed: %s\n", err)
+	}
+
+	var out structS
+	if err := UnmarshalStruct(data, &out); err != nil {
+		t.Fatalf("UnmarshalStruct failed: %s\n", err)
+	}
+	if out.Optional == nil || *out.Optional != val {
+		t.Errorf("Optional: got %v, expected %d", out.Optional, val)
+	}
+}
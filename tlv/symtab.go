@@ -0,0 +1,207 @@
+//
+// symtab.go
+//
+// Copyright (c) 2023 Markku Rossi
+//
+// All rights reserved.
+//
+
+package tlv
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// MarshalJSONWith encodes v as a JSON object, using s to translate
+// TLV types into symbol names. Tags with no matching symbol are
+// keyed by their decimal type number instead. VTData values are
+// encoded as base64 strings and nested VTMap values as nested JSON
+// objects, both following encoding/json's usual rules for []byte and
+// map values.
+func (v Values) MarshalJSONWith(s Symtab) ([]byte, error) {
+	obj, err := v.toJSONObject(s)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(obj)
+}
+
+func (v Values) toJSONObject(s Symtab) (map[string]interface{}, error) {
+	obj := make(map[string]interface{}, len(v))
+	for _, t := range v.Keys() {
+		symbol, ok := s[t]
+		name := symbol.Name
+		if !ok {
+			name = strconv.FormatUint(uint64(t), 10)
+		}
+
+		switch val := v[t].(type) {
+		case Values:
+			child, err := val.toJSONObject(symbol.Child)
+			if err != nil {
+				return nil, err
+			}
+			obj[name] = child
+
+		default:
+			obj[name] = val
+		}
+	}
+	return obj, nil
+}
+
+// UnmarshalJSONWith decodes the JSON object data into Values, using
+// s to map symbol names back to TLV types and to determine the
+// VType each field should decode as. Keys with no matching symbol
+// are parsed as decimal type numbers instead.
+func UnmarshalJSONWith(data []byte, s Symtab) (Values, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	return unmarshalJSONObject(raw, s)
+}
+
+func unmarshalJSONObject(raw map[string]json.RawMessage, s Symtab) (Values, error) {
+	names := make(map[string]Type, len(s))
+	for t, symbol := range s {
+		names[symbol.Name] = t
+	}
+
+	result := make(Values, len(raw))
+	for name, msg := range raw {
+		t, ok := names[name]
+		if !ok {
+			n, err := strconv.ParseUint(name, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("tlv: unknown symbol %q", name)
+			}
+			t = Type(n)
+		}
+
+		symbol, found := s[t]
+		val, err := unmarshalJSONValue(msg, symbol, found)
+		if err != nil {
+			return nil, fmt.Errorf("tlv: symbol %q: %w", name, err)
+		}
+		result[t] = val
+	}
+	return result, nil
+}
+
+func unmarshalJSONValue(msg json.RawMessage, symbol Symbol, found bool) (interface{}, error) {
+	if !found {
+		var v interface{}
+		err := json.Unmarshal(msg, &v)
+		return v, err
+	}
+
+	switch symbol.VType {
+	case VTMap:
+		var raw map[string]json.RawMessage
+		if err := json.Unmarshal(msg, &raw); err != nil {
+			return nil, err
+		}
+		return unmarshalJSONObject(raw, symbol.Child)
+
+	case VTData:
+		var data []byte
+		err := json.Unmarshal(msg, &data)
+		return data, err
+
+	case VTString:
+		var str string
+		err := json.Unmarshal(msg, &str)
+		return str, err
+
+	case VTBool:
+		var b bool
+		err := json.Unmarshal(msg, &b)
+		return b, err
+
+	case VTSInt:
+		var n int64
+		err := json.Unmarshal(msg, &n)
+		return n, err
+
+	case VTFloat:
+		var f float64
+		err := json.Unmarshal(msg, &f)
+		return f, err
+
+	case VTInt:
+		var n uint64
+		err := json.Unmarshal(msg, &n)
+		return n, err
+
+	default:
+		var v interface{}
+		err := json.Unmarshal(msg, &v)
+		return v, err
+	}
+}
+
+// Validate walks v and reports the first tag with no matching
+// symbol in s, or whose value's VType does not match the symbol's
+// declared VType.
+func (s Symtab) Validate(v Values) error {
+	for _, t := range v.Keys() {
+		symbol, ok := s[t]
+		if !ok {
+			return fmt.Errorf("tlv: unknown tag %d", t)
+		}
+
+		if val, ok := v[t].(Values); ok {
+			if symbol.VType != VTMap {
+				return fmt.Errorf("tlv: symbol %q: expected %s, got %s",
+					symbol.Name, symbol.VType, VTMap)
+			}
+			if err := symbol.Child.Validate(val); err != nil {
+				return err
+			}
+			continue
+		}
+
+		vt, err := valueVType(v[t])
+		if err != nil {
+			return fmt.Errorf("tlv: symbol %q: %w", symbol.Name, err)
+		}
+		if vt != symbol.VType {
+			return fmt.Errorf("tlv: symbol %q: expected %s, got %s",
+				symbol.Name, symbol.VType, vt)
+		}
+	}
+	return nil
+}
+
+// valueVType returns the VType that Marshal would use to encode val.
+func valueVType(val interface{}) (VType, error) {
+	switch val.(type) {
+	case bool:
+		return VTBool, nil
+
+	case uint8, uint16, uint32, uint64:
+		return VTInt, nil
+
+	case int8, int16, int32, int64, int:
+		return VTSInt, nil
+
+	case float32, float64:
+		return VTFloat, nil
+
+	case string:
+		return VTString, nil
+
+	case []byte:
+		return VTData, nil
+
+	default:
+		if reflect.ValueOf(val).Kind() == reflect.Slice {
+			return VTArray, nil
+		}
+		return 0, fmt.Errorf("unsupported value type %T", val)
+	}
+}
@@ -0,0 +1,122 @@
+//
+// symtab_test.go
+//
+// Copyright (c) 2023 Markku Rossi
+//
+// All rights reserved.
+//
+
+package tlv
+
+import (
+	"bytes"
+	"testing"
+)
+
+var symtab = Symtab{
+	0: Symbol{
+		Name:  "name",
+		VType: VTString,
+	},
+	1: Symbol{
+		Name:  "data",
+		VType: VTData,
+	},
+	2: Symbol{
+		Name:  "scope",
+		VType: VTMap,
+		Child: Symtab{
+			0: Symbol{
+				Name:  "admin",
+				VType: VTBool,
+			},
+		},
+	},
+}
+
+func TestMarshalJSONWith(t *testing.T) {
+	values := Values{
+		Type(0): "foo",
+		Type(1): []byte{1, 2, 3},
+		Type(2): Values{
+			Type(0): true,
+		},
+	}
+
+	data, err := values.MarshalJSONWith(symtab)
+	if err != nil {
+		t.Fatalf("MarshalJSONWith failed: %s\n", err)
+	}
+
+	decoded, err := UnmarshalJSONWith(data, symtab)
+	if err != nil {
+		t.Fatalf("UnmarshalJSONWith failed: %s\n", err)
+	}
+
+	if decoded[Type(0)].(string) != "foo" {
+		t.Errorf("name: got %v, expected foo", decoded[Type(0)])
+	}
+	if !bytes.Equal(decoded[Type(1)].([]byte), []byte{1, 2, 3}) {
+		t.Errorf("data: got %v, expected [1 2 3]", decoded[Type(1)])
+	}
+	scope, ok := decoded[Type(2)].(Values)
+	if !ok || scope[Type(0)].(bool) != true {
+		t.Errorf("scope: got %v, expected {admin: true}", decoded[Type(2)])
+	}
+
+	if err := symtab.Validate(decoded); err != nil {
+		t.Errorf("Validate failed: %s\n", err)
+	}
+}
+
+func TestMarshalJSONWithUnknownTag(t *testing.T) {
+	values := Values{
+		Type(42): uint32(12345),
+	}
+
+	data, err := values.MarshalJSONWith(Symtab{})
+	if err != nil {
+		t.Fatalf("MarshalJSONWith failed: %s\n", err)
+	}
+
+	decoded, err := UnmarshalJSONWith(data, Symtab{})
+	if err != nil {
+		t.Fatalf("UnmarshalJSONWith failed: %s\n", err)
+	}
+
+	// JSON numbers with no symbol round-trip as float64, since the
+	// decoder has no declared VType to decode against.
+	if v, ok := decoded[Type(42)].(float64); !ok || v != 12345 {
+		t.Errorf("Type(42): got %v, expected 12345", decoded[Type(42)])
+	}
+}
+
+func TestValidateUnknownTag(t *testing.T) {
+	values := Values{
+		Type(99): "unknown",
+	}
+	if err := symtab.Validate(values); err == nil {
+		t.Errorf("Validate() should fail for unknown tag")
+	}
+}
+
+func TestValidateSIntKinds(t *testing.T) {
+	s := Symtab{
+		0: Symbol{Name: "n", VType: VTSInt},
+	}
+
+	for _, v := range []interface{}{int8(-1), int16(-1), int32(-1), int64(-1), int(-1)} {
+		if err := s.Validate(Values{0: v}); err != nil {
+			t.Errorf("Validate(%T) failed: %s\n", v, err)
+		}
+	}
+}
+
+func TestValidateTypeMismatch(t *testing.T) {
+	values := Values{
+		Type(0): uint32(42),
+	}
+	if err := symtab.Validate(values); err == nil {
+		t.Errorf("Validate() should fail for mismatched VType")
+	}
+}
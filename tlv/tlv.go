@@ -14,6 +14,8 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
+	"reflect"
 	"sort"
 )
 
@@ -53,7 +55,16 @@ type Symtab map[Type]Symbol
 
 // Symbol specifies a symtab entry.
 type Symbol struct {
-	Name  string
+	Name string
+
+	// VType declares the value type that Symtab.Validate and
+	// UnmarshalJSONWith expect for this symbol. The zero value is
+	// VTBool, so a symbol that leaves VType unset is validated and
+	// decoded as a bool; Dump does not consult VType, so symbols used
+	// only for dumping may leave it unset.
+	VType VType
+
+	// Child is the nested Symtab used when VType is VTMap.
 	Child Symtab
 }
 
@@ -113,6 +124,9 @@ const (
 	VTString
 	VTData
 	VTMap
+	VTSInt
+	VTFloat
+	VTArray
 )
 
 var vtypes = map[VType]string{
@@ -121,6 +135,9 @@ var vtypes = map[VType]string{
 	VTString: "string",
 	VTData:   "data",
 	VTMap:    "map",
+	VTSInt:   "sint",
+	VTFloat:  "float",
+	VTArray:  "array",
 }
 
 func (vt VType) String() string {
@@ -229,8 +246,38 @@ func (v Values) Marshal() ([]byte, error) {
 			marshalInt(uint64(len(d)), buf)
 			buf.Write(d)
 
+		case int8, int16, int32, int64, int:
+			tag.SetVType(VTSInt)
+			marshalInt(uint64(tag), buf)
+			marshalSInt(reflect.ValueOf(val).Int(), buf)
+
+		case float32:
+			tag.SetVType(VTFloat)
+			marshalInt(uint64(tag), buf)
+			marshalInt(4, buf)
+			bo.PutUint32(tmp[:4], math.Float32bits(val))
+			buf.Write(tmp[:4])
+
+		case float64:
+			tag.SetVType(VTFloat)
+			marshalInt(uint64(tag), buf)
+			marshalInt(8, buf)
+			bo.PutUint64(tmp[:], math.Float64bits(val))
+			buf.Write(tmp[:])
+
 		default:
-			return nil, fmt.Errorf("type %T (val=%v) not supported", val, val)
+			rv := reflect.ValueOf(val)
+			if rv.Kind() != reflect.Slice {
+				return nil, fmt.Errorf("type %T (val=%v) not supported", val, val)
+			}
+			tag.SetVType(VTArray)
+			marshalInt(uint64(tag), buf)
+			d, err := marshalArray(rv)
+			if err != nil {
+				return nil, err
+			}
+			marshalInt(uint64(len(d)), buf)
+			buf.Write(d)
 		}
 	}
 
@@ -300,6 +347,31 @@ func Unmarshal(data []byte) (Values, error) {
 				return nil, err
 			}
 
+		case VTSInt:
+			sval, _, err := unmarshalVarint(data[ofs:ofs+int(length)], 0)
+			if err != nil {
+				return nil, err
+			}
+			val = zigzagDecode(sval)
+
+		case VTFloat:
+			switch length {
+			case 4:
+				val = math.Float32frombits(bo.Uint32(data[ofs:]))
+
+			case 8:
+				val = math.Float64frombits(bo.Uint64(data[ofs:]))
+
+			default:
+				return nil, fmt.Errorf("invalid float data length %d", length)
+			}
+
+		case VTArray:
+			val, err = unmarshalArray(data[ofs : ofs+int(length)])
+			if err != nil {
+				return nil, err
+			}
+
 		default:
 			return nil, fmt.Errorf("invalid value type %s", tag.VType())
 		}
@@ -357,3 +429,312 @@ func unmarshalInt(data []byte, ofs int) (uint64, int, error) {
 	}
 	return 0, ofs, ErrorEOF
 }
+
+// zigzagEncode maps a signed integer to an unsigned one, so that
+// numbers with a small absolute value (for either sign) have a small
+// septet-varint encoding.
+func zigzagEncode(v int64) uint64 {
+	return uint64((v << 1) ^ (v >> 63))
+}
+
+// zigzagDecode reverses zigzagEncode.
+func zigzagDecode(v uint64) int64 {
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+// marshalSInt writes v as a zig-zag-encoded septet-varint,
+// length-prefixed so VTSInt values can be read back with
+// unmarshalVarint. Unlike marshalInt, which is capped at 5 septets
+// (35 bits) to match the tag/length wire format, this writes as many
+// septets as needed, so the full int64 range round-trips.
+func marshalSInt(v int64, buf *bytes.Buffer) {
+	sub := new(bytes.Buffer)
+	marshalVarint(zigzagEncode(v), sub)
+	marshalInt(uint64(sub.Len()), buf)
+	buf.Write(sub.Bytes())
+}
+
+// marshalVarint writes val using the same big-endian septet framing
+// as marshalInt, but without its fixed 5-septet cap, so all 64 bits
+// of val can be represented.
+func marshalVarint(val uint64, buf *bytes.Buffer) {
+	var septets [10]byte
+	n := 0
+	for {
+		septets[n] = byte(val & 0x7f)
+		val >>= 7
+		n++
+		if val == 0 {
+			break
+		}
+	}
+	for i := n - 1; i >= 0; i-- {
+		b := septets[i]
+		if i > 0 {
+			b |= 0x80
+		}
+		buf.WriteByte(b)
+	}
+}
+
+// unmarshalVarint reads a septet-varint written by marshalVarint,
+// with no fixed septet cap other than the 10 septets needed to cover
+// 64 bits.
+func unmarshalVarint(data []byte, ofs int) (uint64, int, error) {
+	var result uint64
+	for i := 0; i < 10; i++ {
+		if ofs >= len(data) {
+			return 0, ofs, ErrorEOF
+		}
+		bit := data[ofs] & 0x80
+		val := data[ofs] & 0x7f
+
+		ofs++
+
+		result <<= 7
+		result |= uint64(val)
+
+		if bit == 0 {
+			return result, ofs, nil
+		}
+	}
+	return 0, ofs, ErrorEOF
+}
+
+// arrayElemVType maps the Kind of a slice element to the VType used
+// to encode it in a VTArray.
+func arrayElemVType(k reflect.Kind) (VType, error) {
+	switch k {
+	case reflect.Bool:
+		return VTBool, nil
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint:
+		return VTInt, nil
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int:
+		return VTSInt, nil
+	case reflect.Float32, reflect.Float64:
+		return VTFloat, nil
+	case reflect.String:
+		return VTString, nil
+	default:
+		return 0, fmt.Errorf("array element type %s not supported", k)
+	}
+}
+
+// arrayElemWidth returns the wire width, in bytes, of a VTArray's
+// VTInt or VTFloat elements; it is unused (and 0) for other VTypes.
+func arrayElemWidth(vt VType, k reflect.Kind) int {
+	switch vt {
+	case VTInt:
+		return intWidth(k)
+	case VTFloat:
+		if k == reflect.Float32 {
+			return 4
+		}
+		return 8
+	default:
+		return 0
+	}
+}
+
+// marshalArray encodes rv, a homogeneous slice, as a VTArray
+// payload: a leading element-VType byte, a leading element-width
+// byte (the VTInt/VTFloat width in bytes, 1/2/4/8; 0 for VTypes that
+// don't vary in width), and then each element encoded as a
+// length-prefixed value, so the tag is not repeated once per
+// element. The width byte lets unmarshalArray pick the right slice
+// type (e.g. []uint32 vs. []uint64) even for a zero-element array,
+// whose elements carry no width information of their own.
+func marshalArray(rv reflect.Value) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	var tmp [8]byte
+
+	elemKind := rv.Type().Elem().Kind()
+	vt, err := arrayElemVType(elemKind)
+	if err != nil {
+		return nil, err
+	}
+	buf.WriteByte(byte(vt))
+	buf.WriteByte(byte(arrayElemWidth(vt, elemKind)))
+
+	for i := 0; i < rv.Len(); i++ {
+		ev := rv.Index(i)
+
+		switch vt {
+		case VTBool:
+			marshalInt(1, buf)
+			if ev.Bool() {
+				buf.WriteByte(1)
+			} else {
+				buf.WriteByte(0)
+			}
+
+		case VTInt:
+			width := intWidth(ev.Kind())
+			marshalInt(uint64(width), buf)
+			bo.PutUint64(tmp[:], ev.Uint())
+			buf.Write(tmp[8-width:])
+
+		case VTSInt:
+			marshalSInt(ev.Int(), buf)
+
+		case VTFloat:
+			if ev.Kind() == reflect.Float32 {
+				marshalInt(4, buf)
+				bo.PutUint32(tmp[:4], math.Float32bits(float32(ev.Float())))
+				buf.Write(tmp[:4])
+			} else {
+				marshalInt(8, buf)
+				bo.PutUint64(tmp[:], math.Float64bits(ev.Float()))
+				buf.Write(tmp[:])
+			}
+
+		case VTString:
+			data := []byte(ev.String())
+			marshalInt(uint64(len(data)), buf)
+			buf.Write(data)
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// unmarshalArray decodes a VTArray payload, as written by
+// marshalArray, into a slice of the Go type matching the array's
+// element VType and width. The width byte in the header (rather than
+// the length of the first element) determines the result type for
+// VTInt and VTFloat, so that a zero-element array still decodes to
+// the right slice type.
+func unmarshalArray(data []byte) (interface{}, error) {
+	if len(data) < 2 {
+		return nil, ErrorTruncated
+	}
+	vt := VType(data[0])
+	width := int(data[1])
+	ofs := 2
+
+	switch vt {
+	case VTBool:
+		var out []bool
+		for ofs < len(data) {
+			length, next, err := unmarshalInt(data, ofs)
+			if err != nil {
+				return nil, err
+			}
+			if next+int(length) > len(data) {
+				return nil, ErrorTruncated
+			}
+			out = append(out, data[next] != 0)
+			ofs = next + int(length)
+		}
+		return out, nil
+
+	case VTInt:
+		var out8 []uint8
+		var out16 []uint16
+		var out32 []uint32
+		var out64 []uint64
+		for ofs < len(data) {
+			length, next, err := unmarshalInt(data, ofs)
+			if err != nil {
+				return nil, err
+			}
+			if next+int(length) > len(data) {
+				return nil, ErrorTruncated
+			}
+			if int(length) != width {
+				return nil, fmt.Errorf("invalid integer array element length %d, expected %d", length, width)
+			}
+			payload := data[next : next+int(length)]
+			switch width {
+			case 1:
+				out8 = append(out8, payload[0])
+			case 2:
+				out16 = append(out16, bo.Uint16(payload))
+			case 4:
+				out32 = append(out32, bo.Uint32(payload))
+			case 8:
+				out64 = append(out64, bo.Uint64(payload))
+			default:
+				return nil, fmt.Errorf("invalid integer array element width %d", width)
+			}
+			ofs = next + int(length)
+		}
+		switch width {
+		case 1:
+			return out8, nil
+		case 2:
+			return out16, nil
+		case 4:
+			return out32, nil
+		default:
+			return out64, nil
+		}
+
+	case VTSInt:
+		var out []int64
+		for ofs < len(data) {
+			length, next, err := unmarshalInt(data, ofs)
+			if err != nil {
+				return nil, err
+			}
+			if next+int(length) > len(data) {
+				return nil, ErrorTruncated
+			}
+			sval, _, err := unmarshalVarint(data[next:next+int(length)], 0)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, zigzagDecode(sval))
+			ofs = next + int(length)
+		}
+		return out, nil
+
+	case VTFloat:
+		var out32 []float32
+		var out64 []float64
+		for ofs < len(data) {
+			length, next, err := unmarshalInt(data, ofs)
+			if err != nil {
+				return nil, err
+			}
+			if next+int(length) > len(data) {
+				return nil, ErrorTruncated
+			}
+			if int(length) != width {
+				return nil, fmt.Errorf("invalid float array element length %d, expected %d", length, width)
+			}
+			payload := data[next : next+int(length)]
+			switch width {
+			case 4:
+				out32 = append(out32, math.Float32frombits(bo.Uint32(payload)))
+			case 8:
+				out64 = append(out64, math.Float64frombits(bo.Uint64(payload)))
+			default:
+				return nil, fmt.Errorf("invalid float array element width %d", width)
+			}
+			ofs = next + int(length)
+		}
+		if width == 4 {
+			return out32, nil
+		}
+		return out64, nil
+
+	case VTString:
+		var out []string
+		for ofs < len(data) {
+			length, next, err := unmarshalInt(data, ofs)
+			if err != nil {
+				return nil, err
+			}
+			if next+int(length) > len(data) {
+				return nil, ErrorTruncated
+			}
+			out = append(out, string(data[next:next+int(length)]))
+			ofs = next + int(length)
+		}
+		return out, nil
+
+	default:
+		return nil, fmt.Errorf("invalid array element type %s", vt)
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"encoding/base64"
 	"encoding/hex"
 	"fmt"
+	"math"
 	"os"
 	"testing"
 )
@@ -107,6 +108,97 @@ func TestMarshal(t *testing.T) {
 	}
 }
 
+func TestMarshalExtendedTypes(t *testing.T) {
+	values := Values{
+		Type(0): int8(-1),
+		Type(1): int16(-256),
+		Type(2): int32(-1 << 20),
+		Type(3): int64(-1) << 40,
+		Type(4): float32(3.5),
+		Type(5): float64(-2.25),
+		Type(6): []uint32{1, 2, 3},
+		Type(7): []string{"a", "bb", "ccc"},
+	}
+
+	data, err := values.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal failed: %s\n", err)
+	}
+
+	decoded, err := Unmarshal(data)
+	if err != nil {
+		t.Fatalf("Unmarshal failed: %s\n", err)
+	}
+
+	if v := decoded[Type(0)].(int64); v != -1 {
+		t.Errorf("Type(0): got %d, expected -1", v)
+	}
+	if v := decoded[Type(1)].(int64); v != -256 {
+		t.Errorf("Type(1): got %d, expected -256", v)
+	}
+	if v := decoded[Type(2)].(int64); v != -1<<20 {
+		t.Errorf("Type(2): got %d, expected %d", v, -1<<20)
+	}
+	if v := decoded[Type(3)].(int64); v != int64(-1)<<40 {
+		t.Errorf("Type(3): got %d, expected %d", v, int64(-1)<<40)
+	}
+	if v := decoded[Type(4)].(float32); v != 3.5 {
+		t.Errorf("Type(4): got %v, expected 3.5", v)
+	}
+	if v := decoded[Type(5)].(float64); v != -2.25 {
+		t.Errorf("Type(5): got %v, expected -2.25", v)
+	}
+	arr, ok := decoded[Type(6)].([]uint32)
+	if !ok || len(arr) != 3 || arr[0] != 1 || arr[1] != 2 || arr[2] != 3 {
+		t.Errorf("Type(6): got %v, expected [1 2 3]", decoded[Type(6)])
+	}
+	strs, ok := decoded[Type(7)].([]string)
+	if !ok || len(strs) != 3 || strs[0] != "a" || strs[1] != "bb" || strs[2] != "ccc" {
+		t.Errorf("Type(7): got %v, expected [a bb ccc]", decoded[Type(7)])
+	}
+}
+
+func TestMarshalEmptyArray(t *testing.T) {
+	values := Values{
+		Type(0): []uint32(nil),
+		Type(1): []float32(nil),
+	}
+
+	data, err := values.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal failed: %s\n", err)
+	}
+	decoded, err := Unmarshal(data)
+	if err != nil {
+		t.Fatalf("Unmarshal failed: %s\n", err)
+	}
+
+	if _, ok := decoded[Type(0)].([]uint32); !ok {
+		t.Errorf("Type(0): got %T, expected []uint32", decoded[Type(0)])
+	}
+	if _, ok := decoded[Type(1)].([]float32); !ok {
+		t.Errorf("Type(1): got %T, expected []float32", decoded[Type(1)])
+	}
+}
+
+func TestMarshalSIntFullRange(t *testing.T) {
+	cases := []int64{0, 1, -1, 1 << 40, -(1 << 40), math.MaxInt64, math.MinInt64}
+
+	for _, v := range cases {
+		data, err := (Values{Type(0): v}).Marshal()
+		if err != nil {
+			t.Fatalf("Marshal(%d) failed: %s\n", v, err)
+		}
+		decoded, err := Unmarshal(data)
+		if err != nil {
+			t.Fatalf("Unmarshal(%d) failed: %s\n", v, err)
+		}
+		if got := decoded[Type(0)].(int64); got != v {
+			t.Errorf("round-trip %d: got %d", v, got)
+		}
+	}
+}
+
 func FuzzMarshal(f *testing.F) {
 	testcases := []Values{
 		{
@@ -128,6 +220,18 @@ func FuzzMarshal(f *testing.F) {
 		{
 			Type(8): []byte{1, 2, 3, 4},
 		},
+		{
+			Type(9): int32(-12345),
+		},
+		{
+			Type(10): float64(3.14159),
+		},
+		{
+			Type(11): []uint32{1, 2, 3},
+		},
+		{
+			Type(12): []string{"a", "bb", "ccc"},
+		},
 	}
 	for _, tc := range testcases {
 		data, err := tc.Marshal()
@@ -0,0 +1,123 @@
+//
+// marshal.go
+//
+// Copyright (c) 2023 Markku Rossi
+//
+// All rights reserved.
+//
+
+package uuid
+
+import (
+	"database/sql/driver"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+)
+
+func init() {
+	gob.Register(UUID{})
+}
+
+// MarshalText implements the encoding.TextMarshaler interface. It
+// returns the UUID in its canonical string form.
+func (id UUID) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler
+// interface. It accepts the same formats as Parse.
+func (id *UUID) UnmarshalText(text []byte) error {
+	parsed, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*id = parsed
+	return nil
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler
+// interface. It returns the UUID's raw 16 bytes.
+func (id UUID) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 16)
+	copy(data, id[:])
+	return data, nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler
+// interface. It expects 16 bytes of raw UUID data, as returned by
+// MarshalBinary.
+func (id *UUID) UnmarshalBinary(data []byte) error {
+	parsed, err := ParseData(data)
+	if err != nil {
+		return err
+	}
+	*id = parsed
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface. It encodes
+// the UUID as a JSON string in its canonical form.
+func (id UUID) MarshalJSON() ([]byte, error) {
+	return json.Marshal(id.String())
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. It
+// accepts a JSON string in any of the formats recognized by Parse,
+// i.e. the canonical, URN, and braced forms.
+func (id *UUID) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	parsed, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*id = parsed
+	return nil
+}
+
+// Value implements the database/sql/driver.Valuer interface. It
+// returns the UUID in its canonical string form.
+func (id UUID) Value() (driver.Value, error) {
+	return id.String(), nil
+}
+
+// Scan implements the database/sql.Scanner interface. It accepts a
+// raw 16-byte value, a string in any of the formats recognized by
+// Parse, or nil (which resets the UUID to Nil).
+func (id *UUID) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*id = Nil
+		return nil
+
+	case []byte:
+		if len(v) == 16 {
+			parsed, err := ParseData(v)
+			if err != nil {
+				return err
+			}
+			*id = parsed
+			return nil
+		}
+		parsed, err := Parse(string(v))
+		if err != nil {
+			return err
+		}
+		*id = parsed
+		return nil
+
+	case string:
+		parsed, err := Parse(v)
+		if err != nil {
+			return err
+		}
+		*id = parsed
+		return nil
+
+	default:
+		return fmt.Errorf("uuid: cannot scan %T into UUID", src)
+	}
+}
@@ -0,0 +1,114 @@
+//
+// marshal_test.go
+//
+// Copyright (c) 2023 Markku Rossi
+//
+// All rights reserved.
+//
+
+package uuid
+
+import (
+	"database/sql/driver"
+	"encoding"
+	"encoding/json"
+	"testing"
+)
+
+var (
+	_ encoding.TextMarshaler   = UUID{}
+	_ encoding.TextUnmarshaler = &UUID{}
+	_ encoding.BinaryMarshaler = UUID{}
+	_ driver.Valuer            = UUID{}
+)
+
+func TestMarshalText(t *testing.T) {
+	id := MustParse(uuids[0])
+
+	text, err := id.MarshalText()
+	if err != nil {
+		t.Fatalf("MarshalText failed: %s\n", err)
+	}
+
+	var got UUID
+	if err := got.UnmarshalText(text); err != nil {
+		t.Fatalf("UnmarshalText failed: %s\n", err)
+	}
+	if got.Compare(id) != 0 {
+		t.Errorf("UnmarshalText() = %s, expected %s", got, id)
+	}
+}
+
+func TestMarshalBinary(t *testing.T) {
+	id := MustParse(uuids[0])
+
+	data, err := id.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary failed: %s\n", err)
+	}
+
+	var got UUID
+	if err := got.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary failed: %s\n", err)
+	}
+	if got.Compare(id) != 0 {
+		t.Errorf("UnmarshalBinary() = %s, expected %s", got, id)
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	id := MustParse(uuids[0])
+
+	data, err := json.Marshal(id)
+	if err != nil {
+		t.Fatalf("json.Marshal failed: %s\n", err)
+	}
+
+	var got UUID
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("json.Unmarshal failed: %s\n", err)
+	}
+	if got.Compare(id) != 0 {
+		t.Errorf("json.Unmarshal() = %s, expected %s", got, id)
+	}
+
+	for _, s := range uuids {
+		var u UUID
+		if err := json.Unmarshal([]byte(`"`+s+`"`), &u); err != nil {
+			t.Errorf("json.Unmarshal(%q) failed: %s\n", s, err)
+		}
+	}
+}
+
+func TestValueAndScan(t *testing.T) {
+	id := MustParse(uuids[0])
+
+	val, err := id.Value()
+	if err != nil {
+		t.Fatalf("Value failed: %s\n", err)
+	}
+
+	var fromString UUID
+	if err := fromString.Scan(val); err != nil {
+		t.Fatalf("Scan(string) failed: %s\n", err)
+	}
+	if fromString.Compare(id) != 0 {
+		t.Errorf("Scan(string) = %s, expected %s", fromString, id)
+	}
+
+	var fromBytes UUID
+	if err := fromBytes.Scan(id[:]); err != nil {
+		t.Fatalf("Scan([]byte) failed: %s\n", err)
+	}
+	if fromBytes.Compare(id) != 0 {
+		t.Errorf("Scan([]byte) = %s, expected %s", fromBytes, id)
+	}
+
+	var fromNil UUID
+	if err := fromNil.Scan(nil); err != nil {
+		t.Fatalf("Scan(nil) failed: %s\n", err)
+	}
+	if fromNil.Compare(Nil) != 0 {
+		t.Errorf("Scan(nil) = %s, expected Nil", fromNil)
+	}
+}
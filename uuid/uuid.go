@@ -12,12 +12,16 @@ package uuid
 
 import (
 	"bytes"
+	"crypto/md5"
 	"crypto/rand"
+	"crypto/sha1"
 	"encoding/binary"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"hash"
 	"regexp"
+	"time"
 )
 
 // UUID is an Universally Unique IDentifier, as defined by RFC
@@ -75,6 +79,16 @@ func (v Variant) String() string {
 // Nil UUID is a special case UUID which has all bits set to zero.
 var Nil UUID
 
+// NamespaceDNS, NamespaceURL, NamespaceOID, and NamespaceX500 are the
+// name-based UUID namespaces predefined by RFC 4122, for use with
+// NewV3 and NewV5.
+var (
+	NamespaceDNS  = MustParse("6ba7b810-9dad-11d1-80b4-00c04fd430c8")
+	NamespaceURL  = MustParse("6ba7b811-9dad-11d1-80b4-00c04fd430c8")
+	NamespaceOID  = MustParse("6ba7b812-9dad-11d1-80b4-00c04fd430c8")
+	NamespaceX500 = MustParse("6ba7b814-9dad-11d1-80b4-00c04fd430c8")
+)
+
 // {urn:uuid:f81d4fae-7dec-11d0-a765-00a0c91e6bf6}
 var reUUID = regexp.MustCompile("^\\{?(urn:uuid:)?([[:xdigit:]]{8})-([[:xdigit:]]{4})-([[:xdigit:]]{4})-([[:xdigit:]]{4})-([[:xdigit:]]{12})\\}?$")
 
@@ -188,6 +202,14 @@ func (id UUID) Compare(id2 UUID) int {
 	return bytes.Compare(id.Node(), id2.Node())
 }
 
+// CompareBytes compares the two UUIDs by their raw 16-byte
+// representation, ignoring field semantics. Unlike Compare, which
+// assumes version 1 field ordering, this yields a chronological
+// ordering for time-ordered UUIDs such as version 7.
+func (id UUID) CompareBytes(id2 UUID) int {
+	return bytes.Compare(id[:], id2[:])
+}
+
 func Parse(value string) (UUID, error) {
 	m := reUUID.FindStringSubmatch(value)
 	if m == nil {
@@ -252,3 +274,75 @@ func New() (UUID, error) {
 
 	return id, nil
 }
+
+// NewV7 returns a new version 7 UUID: a Unix Epoch time-ordered UUID,
+// as specified by draft-ietf-uuidrev/rfc4122bis (RFC 9562). The
+// first 48 bits hold the current Unix time in milliseconds, encoded
+// big-endian, so that byte-wise comparison of v7 UUIDs (see
+// CompareBytes) also orders them chronologically. The remaining bits
+// are filled with random data from crypto/rand.
+func NewV7() (UUID, error) {
+	id := UUID{}
+
+	_, err := rand.Read(id[:])
+	if err != nil {
+		return Nil, err
+	}
+
+	ms := uint64(time.Now().UnixMilli())
+	id[0] = byte(ms >> 40)
+	id[1] = byte(ms >> 32)
+	id[2] = byte(ms >> 24)
+	id[3] = byte(ms >> 16)
+	id[4] = byte(ms >> 8)
+	id[5] = byte(ms)
+
+	// Version: 7
+	id[6] = 0x70 | (id[6] & 0x0f)
+
+	// Variant: RFC4122
+	id[8] = 0x80 | (id[8] & 0x3f)
+
+	return id, nil
+}
+
+// TimeV7 decodes the 48-bit Unix Epoch millisecond timestamp carried
+// in the first 6 bytes of a version 7 UUID and returns it as a
+// time.Time. The result is only meaningful for UUIDs created by
+// NewV7.
+func (id UUID) TimeV7() time.Time {
+	ms := uint64(id[0])<<40 | uint64(id[1])<<32 | uint64(id[2])<<24 |
+		uint64(id[3])<<16 | uint64(id[4])<<8 | uint64(id[5])
+	return time.UnixMilli(int64(ms))
+}
+
+// NewV5 returns a new version 5 UUID: a name-based UUID computed by
+// hashing the namespace UUID and name with SHA-1, as specified by RFC
+// 4122.
+func NewV5(namespace UUID, name []byte) UUID {
+	return newNameBased(sha1.New(), 5, namespace, name)
+}
+
+// NewV3 returns a new version 3 UUID: a name-based UUID computed by
+// hashing the namespace UUID and name with MD5, as specified by RFC
+// 4122.
+func NewV3(namespace UUID, name []byte) UUID {
+	return newNameBased(md5.New(), 3, namespace, name)
+}
+
+func newNameBased(h hash.Hash, version uint8, namespace UUID, name []byte) UUID {
+	h.Write(namespace[:])
+	h.Write(name)
+	sum := h.Sum(nil)
+
+	id := UUID{}
+	copy(id[:], sum[:16])
+
+	// Version.
+	id[6] = (version << 4) | (id[6] & 0x0f)
+
+	// Variant: RFC4122
+	id[8] = 0x80 | (id[8] & 0x3f)
+
+	return id
+}
@@ -11,6 +11,7 @@ package uuid
 import (
 	"fmt"
 	"testing"
+	"time"
 )
 
 var uuids = []string{
@@ -68,3 +69,59 @@ func TestNew(t *testing.T) {
 		t.Errorf("Invalid version: %d vs. 4", id.Version())
 	}
 }
+
+func TestNewV7(t *testing.T) {
+	id, err := NewV7()
+	if err != nil {
+		t.Fatalf("Failed to create UUID: %s\n", err)
+	}
+
+	_, variant := id.ClkSeqHiAndVariant()
+	if variant != RFC4122 {
+		t.Errorf("Invalid variant: %d vs. %d", variant, RFC4122)
+	}
+
+	if id.Version() != 7 {
+		t.Errorf("Invalid version: %d vs. 7", id.Version())
+	}
+
+	if d := time.Since(id.TimeV7()); d < 0 || d > time.Minute {
+		t.Errorf("TimeV7() = %s, too far from now", id.TimeV7())
+	}
+
+	time.Sleep(2 * time.Millisecond)
+
+	next, err := NewV7()
+	if err != nil {
+		t.Fatalf("Failed to create UUID: %s\n", err)
+	}
+	if id.CompareBytes(next) > 0 {
+		t.Errorf("UUID.CompareBytes() not chronological: %s vs. %s", id, next)
+	}
+}
+
+func TestNewV5AndV3(t *testing.T) {
+	name := []byte("www.example.com")
+
+	v5a := NewV5(NamespaceDNS, name)
+	v5b := NewV5(NamespaceDNS, name)
+	if v5a.Compare(v5b) != 0 {
+		t.Errorf("NewV5() not deterministic: %s vs. %s", v5a, v5b)
+	}
+	if v5a.Version() != 5 {
+		t.Errorf("Invalid version: %d vs. 5", v5a.Version())
+	}
+
+	v3a := NewV3(NamespaceDNS, name)
+	v3b := NewV3(NamespaceDNS, name)
+	if v3a.Compare(v3b) != 0 {
+		t.Errorf("NewV3() not deterministic: %s vs. %s", v3a, v3b)
+	}
+	if v3a.Version() != 3 {
+		t.Errorf("Invalid version: %d vs. 3", v3a.Version())
+	}
+
+	if v5a.Compare(v3a) == 0 {
+		t.Errorf("NewV5() and NewV3() produced the same UUID for %q", name)
+	}
+}